@@ -0,0 +1,183 @@
+package micrologging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy controls what an AsyncWriter does when its internal buffer
+// is full and a new message arrives.
+type OverflowPolicy uint8
+
+const (
+	// Block waits for room in the buffer, exerting back-pressure on the
+	// logging goroutine.
+	Block OverflowPolicy = iota
+	// DropOldest evicts the oldest buffered message to make room for the
+	// new one.
+	DropOldest
+	// DropNewest discards the incoming message, leaving the buffer as is.
+	DropNewest
+	// DropAndCount behaves like DropNewest, but also tallies the drop so
+	// it can be reported via the periodic "logging dropped N messages"
+	// warning.
+	DropAndCount
+)
+
+const dropReportInterval = 10 * time.Second
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// AsyncWriter wraps an io.Writer so that writes happen on a dedicated
+// goroutine instead of blocking the caller. Pre-formatted messages are
+// queued on a buffered channel; once it's full, OverflowPolicy decides
+// whether to block, drop the newest message, or evict the oldest one.
+type AsyncWriter struct {
+	inner  io.Writer
+	policy OverflowPolicy
+
+	queue   chan *bytes.Buffer
+	pending sync.WaitGroup
+	dropped int64
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+var asyncWriters struct {
+	mu      sync.Mutex
+	writers []*AsyncWriter
+}
+
+//NewAsyncWriter wraps inner so that writes to it happen on a dedicated
+//goroutine, buffering up to bufferSize pending messages and applying
+//policy once that buffer is full.
+func NewAsyncWriter(inner io.Writer, bufferSize int, policy OverflowPolicy) io.Writer {
+	w := &AsyncWriter{
+		inner:  inner,
+		policy: policy,
+		queue:  make(chan *bytes.Buffer, bufferSize),
+		done:   make(chan struct{}),
+	}
+
+	go w.run()
+
+	asyncWriters.mu.Lock()
+	asyncWriters.writers = append(asyncWriters.writers, w)
+	asyncWriters.mu.Unlock()
+
+	return w
+}
+
+//Write implements io.Writer, handing p off to the writer's goroutine
+//instead of writing it inline.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	buf.Write(p)
+
+	w.pending.Add(1)
+
+	switch w.policy {
+	case DropNewest, DropAndCount:
+		select {
+		case w.queue <- buf:
+		default:
+			if w.policy == DropAndCount {
+				atomic.AddInt64(&w.dropped, 1)
+			}
+			bufferPool.Put(buf)
+			w.pending.Done()
+		}
+	case DropOldest:
+		for {
+			select {
+			case w.queue <- buf:
+				return len(p), nil
+			default:
+			}
+
+			select {
+			case oldest := <-w.queue:
+				bufferPool.Put(oldest)
+				w.pending.Done()
+			default:
+			}
+		}
+	default: // Block
+		w.queue <- buf
+	}
+
+	return len(p), nil
+}
+
+func (w *AsyncWriter) run() {
+	ticker := time.NewTicker(dropReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case buf, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			w.inner.Write(buf.Bytes())
+			bufferPool.Put(buf)
+			w.pending.Done()
+		case <-ticker.C:
+			if n := atomic.SwapInt64(&w.dropped, 0); n > 0 {
+				Warn("logging dropped %d messages", n)
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+//Flush blocks until every message queued before the call has been written
+//to the underlying writer, or ctx is done.
+func (w *AsyncWriter) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+	go func() {
+		w.pending.Wait()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//Close stops the writer's goroutine after draining any queued messages.
+func (w *AsyncWriter) Close() error {
+	w.pending.Wait()
+	w.closeOnce.Do(func() { close(w.done) })
+	return nil
+}
+
+//Shutdown flushes every AsyncWriter created via NewAsyncWriter, giving
+//queued messages a chance to reach their destination before the process
+//exits. Fatal does not call it and does not exit the process itself.
+func Shutdown(ctx context.Context) error {
+	asyncWriters.mu.Lock()
+	writers := make([]*AsyncWriter, len(asyncWriters.writers))
+	copy(writers, asyncWriters.writers)
+	asyncWriters.mu.Unlock()
+
+	for _, w := range writers {
+		if err := w.Flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
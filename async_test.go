@@ -0,0 +1,124 @@
+package micrologging
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncWriterFlushWaitsForPendingWrites(t *testing.T) {
+	var out bytes.Buffer
+	aw := NewAsyncWriter(&out, 8, Block).(*AsyncWriter)
+
+	for i := 0; i < 5; i++ {
+		if _, err := aw.Write([]byte("x")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := aw.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if out.Len() != 5 {
+		t.Fatalf("expected 5 bytes written after Flush, got %d", out.Len())
+	}
+}
+
+// gatedWriter blocks inside Write until release is closed, letting a test
+// force the AsyncWriter's queue to fill up deterministically rather than
+// racing with the consumer goroutine.
+type gatedWriter struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	g.once.Do(func() { close(g.started) })
+	<-g.release
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gatedWriter) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+func TestAsyncWriterDropNewestDropsUnderPressure(t *testing.T) {
+	gated := newGatedWriter()
+	aw := NewAsyncWriter(gated, 1, DropNewest).(*AsyncWriter)
+
+	if _, err := aw.Write([]byte("1")); err != nil {
+		t.Fatalf("Write msg1: %v", err)
+	}
+
+	select {
+	case <-gated.started:
+	case <-time.After(time.Second):
+		t.Fatal("consumer never started writing msg1")
+	}
+
+	if _, err := aw.Write([]byte("2")); err != nil {
+		t.Fatalf("Write msg2: %v", err)
+	}
+	if _, err := aw.Write([]byte("3")); err != nil {
+		t.Fatalf("Write msg3: %v", err)
+	}
+
+	close(gated.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := aw.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if got := gated.String(); got != "12" {
+		t.Fatalf("expected msg3 to be dropped, got %q", got)
+	}
+}
+
+func TestAsyncWriterDropAndCountTallies(t *testing.T) {
+	gated := newGatedWriter()
+	aw := NewAsyncWriter(gated, 1, DropAndCount).(*AsyncWriter)
+
+	if _, err := aw.Write([]byte("1")); err != nil {
+		t.Fatalf("Write msg1: %v", err)
+	}
+
+	select {
+	case <-gated.started:
+	case <-time.After(time.Second):
+		t.Fatal("consumer never started writing msg1")
+	}
+
+	if _, err := aw.Write([]byte("2")); err != nil {
+		t.Fatalf("Write msg2: %v", err)
+	}
+	if _, err := aw.Write([]byte("3")); err != nil {
+		t.Fatalf("Write msg3: %v", err)
+	}
+
+	if n := atomic.LoadInt64(&aw.dropped); n != 1 {
+		t.Fatalf("expected 1 dropped message tallied, got %d", n)
+	}
+
+	close(gated.release)
+}
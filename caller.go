@@ -0,0 +1,81 @@
+package micrologging
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// CallerMode controls how much source-location detail is captured for each
+// log record.
+type CallerMode uint8
+
+const (
+	// NoCaller captures no source location. This is the default.
+	NoCaller CallerMode = iota
+	// ShortFile captures the base file name and line, e.g. "file.go:42".
+	ShortFile
+	// FullFile captures the full file path and line, as reported by the
+	// runtime.
+	FullFile
+	// FuncAndFile captures the calling function name alongside the short
+	// file and line, e.g. "pkg.Func (file.go:42)".
+	FuncAndFile
+)
+
+// callerSkipBase is the number of stack frames between the runtime.Caller
+// call in caller() and the site of a package-level helper such as Info -
+// i.e. caller(), rootLogger.printf, the package-level function, the user.
+// Call paths that go through one more wrapper (Logger.Printf and friends)
+// add to this base via printfDepth's skip parameter.
+const callerSkipBase = 2
+
+var callerMode atomic.Uint32
+
+//SetCallerMode controls whether and how source location is captured for
+//every log record from here on. Safe to call concurrently with logging.
+func SetCallerMode(mode CallerMode) {
+	callerMode.Store(uint32(mode))
+}
+
+//caller renders the caller string for the given skip depth per the
+//current CallerMode, or "" when caller capture is disabled. skip is
+//relative to caller's own caller, i.e. skip=0 would identify whoever
+//called caller() - callerSkipBase and printfDepth already account for the
+//logging package's own frames.
+func caller(skip int) string {
+	mode := CallerMode(callerMode.Load())
+	if mode == NoCaller {
+		return ""
+	}
+
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+
+	if mode == ShortFile || mode == FuncAndFile {
+		if idx := strings.LastIndexByte(file, '/'); idx >= 0 {
+			file = file[idx+1:]
+		}
+	}
+
+	location := file + ":" + strconv.Itoa(line)
+
+	if mode != FuncAndFile {
+		return location
+	}
+
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return location
+	}
+
+	name := fn.Name()
+	if idx := strings.LastIndexByte(name, '/'); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return name + " (" + location + ")"
+}
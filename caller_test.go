@@ -0,0 +1,47 @@
+package micrologging
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func wrapInfoForTest(l *Logger, format string, args ...interface{}) {
+	l.PrintfDepth(INFO, 1, format, args...)
+}
+
+func TestCallerCaptureMatchesCallSite(t *testing.T) {
+	SetCallerMode(ShortFile)
+	defer SetCallerMode(NoCaller)
+
+	var buf bytes.Buffer
+	AddRootOutputWithEncoder(&buf, TextEncoder{})
+
+	l := GetLogger("test.caller")
+
+	_, file, line, _ := runtime.Caller(0)
+	l.Info("via Logger.Info")
+	wantLoggerInfo := filepath.Base(file) + ":" + strconv.Itoa(line+1)
+
+	_, file, line, _ = runtime.Caller(0)
+	l.Printf(INFO, "via Logger.Printf")
+	wantLoggerPrintf := filepath.Base(file) + ":" + strconv.Itoa(line+1)
+
+	_, file, line, _ = runtime.Caller(0)
+	Info("via package Info")
+	wantPackageInfo := filepath.Base(file) + ":" + strconv.Itoa(line+1)
+
+	_, file, line, _ = runtime.Caller(0)
+	wrapInfoForTest(l, "via custom wrapper")
+	wantWrapper := filepath.Base(file) + ":" + strconv.Itoa(line+1)
+
+	out := buf.String()
+	for _, want := range []string{wantLoggerInfo, wantLoggerPrintf, wantPackageInfo, wantWrapper} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain caller %q, got:\n%s", want, out)
+		}
+	}
+}
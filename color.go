@@ -0,0 +1,136 @@
+package micrologging
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/term"
+)
+
+// ColorMode controls when AutoEncoderFor picks ColorTextEncoder over the
+// plain TextEncoder.
+type ColorMode uint8
+
+const (
+	// ColorAuto enables color only when the output is a TTY and NO_COLOR
+	// is unset. This is the default.
+	ColorAuto ColorMode = iota
+	// ColorAlways forces color on regardless of TTY detection.
+	ColorAlways
+	// ColorNever forces color off regardless of TTY detection.
+	ColorNever
+)
+
+var colorMode atomic.Uint32
+
+//SetColor overrides AutoEncoderFor's TTY auto-detection, e.g. to force
+//plain text for CI logs or force color for a pipe that still renders ANSI
+//escapes. Safe to call concurrently with logging.
+func SetColor(mode ColorMode) {
+	colorMode.Store(uint32(mode))
+}
+
+const ansiReset = "\x1b[0m"
+
+func ansiColorFor(level Level) string {
+	switch level {
+	case TRACE:
+		return "\x1b[90m"
+	case DEBUG:
+		return "\x1b[36m"
+	case INFO:
+		return "\x1b[34m"
+	case WARN:
+		return "\x1b[33m"
+	case ERROR:
+		return "\x1b[31m"
+	case FATAL:
+		return "\x1b[91m"
+	default:
+		return ""
+	}
+}
+
+// ColorTextEncoder renders the same layout as TextEncoder, but wraps the
+// "[LEVEL]" tag in ANSI escapes matched to the record's level.
+type ColorTextEncoder struct{}
+
+func (ColorTextEncoder) Encode(r Record) []byte {
+	b := &strings.Builder{}
+
+	b.WriteString("(" + r.Time.Format(timeFormat) + ") ")
+
+	b.WriteString(ansiColorFor(r.Level))
+	b.WriteString("[")
+	b.WriteString(r.Level.String())
+	b.WriteString("]")
+	b.WriteString(ansiReset)
+	b.WriteString(" ")
+
+	if r.Name != "" {
+		b.WriteString("(")
+		b.WriteString(r.Name)
+		b.WriteString(") ")
+	}
+
+	if r.Caller != "" {
+		b.WriteString(r.Caller)
+		b.WriteString(" ")
+	}
+
+	b.WriteString(r.Message)
+
+	for k, v := range r.Fields {
+		b.WriteString(" ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(toString(v))
+	}
+
+	return []byte(strings.TrimSpace(b.String()))
+}
+
+//AutoEncoderFor returns an Encoder that renders ColorTextEncoder when w is
+//an *os.File attached to a terminal and colorMode allows it, and
+//TextEncoder otherwise. The TTY check is done once, up front, but
+//colorMode is re-read on every Encode call, so a later SetColor takes
+//effect immediately - including for the os.Stdout output the root logger
+//installs by default in init().
+func AutoEncoderFor(w io.Writer) Encoder {
+	file, ok := w.(*os.File)
+	isTerminal := ok && term.IsTerminal(int(file.Fd()))
+
+	return &autoEncoder{isTerminal: isTerminal}
+}
+
+//autoEncoder defers the ColorTextEncoder-vs-TextEncoder choice to
+//Encode-time, so changes to colorMode or NO_COLOR are picked up by
+//outputs that were already registered via AutoEncoderFor.
+type autoEncoder struct {
+	isTerminal bool
+}
+
+func (a *autoEncoder) Encode(r Record) []byte {
+	return a.resolve().Encode(r)
+}
+
+func (a *autoEncoder) resolve() Encoder {
+	switch ColorMode(colorMode.Load()) {
+	case ColorAlways:
+		return ColorTextEncoder{}
+	case ColorNever:
+		return TextEncoder{}
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return TextEncoder{}
+	}
+
+	if !a.isTerminal {
+		return TextEncoder{}
+	}
+
+	return ColorTextEncoder{}
+}
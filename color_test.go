@@ -0,0 +1,58 @@
+package micrologging
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAutoEncoderForNonTerminalWriterIsAlwaysText(t *testing.T) {
+	SetColor(ColorAuto)
+	defer SetColor(ColorAuto)
+
+	enc := AutoEncoderFor(&bytes.Buffer{})
+
+	out := string(enc.Encode(Record{Level: INFO, Message: "hi"}))
+	if strings.Contains(out, "\x1b[") {
+		t.Fatalf("expected no ANSI escapes for a non-terminal writer, got %q", out)
+	}
+}
+
+func TestAutoEncoderResolvesByColorMode(t *testing.T) {
+	defer SetColor(ColorAuto)
+
+	terminal := &autoEncoder{isTerminal: true}
+	nonTerminal := &autoEncoder{isTerminal: false}
+
+	SetColor(ColorAlways)
+	if _, ok := nonTerminal.resolve().(ColorTextEncoder); !ok {
+		t.Fatalf("ColorAlways should force color even for a non-terminal output")
+	}
+
+	SetColor(ColorNever)
+	if _, ok := terminal.resolve().(TextEncoder); !ok {
+		t.Fatalf("ColorNever should force plain text even for a terminal output")
+	}
+
+	SetColor(ColorAuto)
+	if _, ok := terminal.resolve().(ColorTextEncoder); !ok {
+		t.Fatalf("ColorAuto should color a terminal output")
+	}
+	if _, ok := nonTerminal.resolve().(TextEncoder); !ok {
+		t.Fatalf("ColorAuto should not color a non-terminal output")
+	}
+}
+
+func TestAutoEncoderHonorsNoColorEnv(t *testing.T) {
+	defer SetColor(ColorAuto)
+	defer os.Unsetenv("NO_COLOR")
+
+	os.Setenv("NO_COLOR", "1")
+	SetColor(ColorAuto)
+
+	terminal := &autoEncoder{isTerminal: true}
+	if _, ok := terminal.resolve().(TextEncoder); !ok {
+		t.Fatalf("expected NO_COLOR to force plain text even for a terminal output")
+	}
+}
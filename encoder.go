@@ -0,0 +1,101 @@
+package micrologging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Record is the fully assembled representation of a single log line, built once
+// by rootLogger.printf and handed to every output's Encoder.
+type Record struct {
+	Time    time.Time
+	Level   Level
+	Name    string
+	Caller  string
+	Message string
+	Fields  map[string]interface{}
+}
+
+// Encoder turns a Record into the bytes that get written to an output. Each
+// output registered via AddRootOutputWithEncoder carries its own Encoder, so
+// the same record can be rendered as plain text for stdout and as JSON for a
+// file shipped to a log aggregator.
+type Encoder interface {
+	Encode(r Record) []byte
+}
+
+// TextEncoder is the original human-readable encoder, also used as the
+// default when an output is added via AddRootOutput.
+type TextEncoder struct{}
+
+func (TextEncoder) Encode(r Record) []byte {
+	b := &strings.Builder{}
+
+	b.WriteString("(" + r.Time.Format(timeFormat) + ") ")
+
+	b.WriteString("[")
+	b.WriteString(r.Level.String())
+	b.WriteString("] ")
+
+	if r.Name != "" {
+		b.WriteString("(")
+		b.WriteString(r.Name)
+		b.WriteString(") ")
+	}
+
+	if r.Caller != "" {
+		b.WriteString(r.Caller)
+		b.WriteString(" ")
+	}
+
+	b.WriteString(r.Message)
+
+	for k, v := range r.Fields {
+		b.WriteString(" ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(toString(v))
+	}
+
+	return []byte(strings.TrimSpace(b.String()))
+}
+
+// JSONEncoder renders a Record as a single-line JSON object with the fixed
+// keys "ts", "level", "logger", "msg" plus any structured fields attached via
+// Logger.With. The "caller" key is added only when the record carries a
+// caller, i.e. when caller capture is enabled via SetCallerMode.
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(r Record) []byte {
+	out := make(map[string]interface{}, len(r.Fields)+5)
+	for k, v := range r.Fields {
+		out[k] = v
+	}
+
+	out["ts"] = r.Time.Format(time.RFC3339Nano)
+	out["level"] = strings.TrimSpace(r.Level.String())
+	out["logger"] = r.Name
+	out["msg"] = r.Message
+	if r.Caller != "" {
+		out["caller"] = r.Caller
+	}
+
+	encoded, err := json.Marshal(out)
+	if err != nil {
+		return []byte(err.Error())
+	}
+	return encoded
+}
+
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case error:
+		return val.Error()
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,91 @@
+package micrologging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoggerWithCopiesFieldsImmutably(t *testing.T) {
+	base := GetLogger("test.with")
+	child := base.With(map[string]interface{}{"a": 1})
+
+	if len(base.fields) != 0 {
+		t.Fatalf("With must not mutate the parent logger's fields, got %v", base.fields)
+	}
+	if child.fields["a"] != 1 {
+		t.Fatalf("expected child field a=1, got %v", child.fields)
+	}
+
+	grandchild := child.With(map[string]interface{}{"b": 2})
+	if _, ok := child.fields["b"]; ok {
+		t.Fatalf("With must not mutate the logger it was called on")
+	}
+	if grandchild.fields["a"] != 1 || grandchild.fields["b"] != 2 {
+		t.Fatalf("expected grandchild to carry both ancestor fields, got %v", grandchild.fields)
+	}
+}
+
+func TestLoggerWithErrorSetsErrorField(t *testing.T) {
+	l := GetLogger("test.witherror").WithError(errors.New("boom"))
+	if l.fields["error"] != "boom" {
+		t.Fatalf("expected error field %q, got %v", "boom", l.fields["error"])
+	}
+}
+
+func TestLoggerWithContextPullsStashedFields(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), map[string]interface{}{"request_id": "abc"})
+	l := GetLogger("test.withcontext").WithContext(ctx)
+	if l.fields["request_id"] != "abc" {
+		t.Fatalf("expected request_id field from context, got %v", l.fields)
+	}
+
+	l2 := GetLogger("test.withcontext").WithContext(context.Background())
+	if len(l2.fields) != 0 {
+		t.Fatalf("expected no fields from a plain context, got %v", l2.fields)
+	}
+}
+
+func TestTextEncoderIncludesNameCallerAndFields(t *testing.T) {
+	r := Record{
+		Level:   INFO,
+		Name:    "app.db",
+		Caller:  "file.go:42",
+		Message: "connected",
+		Fields:  map[string]interface{}{"host": "db1"},
+	}
+
+	out := string(TextEncoder{}.Encode(r))
+	for _, want := range []string{"[INFO ]", "(app.db)", "file.go:42", "connected", "host=db1"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected text output to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestJSONEncoderEncodesFixedKeysAndFields(t *testing.T) {
+	r := Record{
+		Level:   WARN,
+		Name:    "app.http",
+		Caller:  "file.go:7",
+		Message: "slow request",
+		Fields:  map[string]interface{}{"status": 500},
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(JSONEncoder{}.Encode(r), &decoded); err != nil {
+		t.Fatalf("JSONEncoder produced invalid JSON: %v", err)
+	}
+
+	if decoded["logger"] != "app.http" || decoded["msg"] != "slow request" || decoded["caller"] != "file.go:7" {
+		t.Fatalf("unexpected decoded record: %v", decoded)
+	}
+	if decoded["status"].(float64) != 500 {
+		t.Fatalf("expected structured field status=500, got %v", decoded["status"])
+	}
+	if decoded["level"] != "WARN" {
+		t.Fatalf("expected level %q (trimmed), got %v", "WARN", decoded["level"])
+	}
+}
@@ -0,0 +1,93 @@
+package micrologging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// levelRegistry holds per-logger level overrides keyed by dotted logger
+// name, e.g. "app.db.pool". Loggers without an explicit entry inherit the
+// level of their closest ancestor, falling back to the root logger's level.
+type levelRegistry struct {
+	mu     sync.RWMutex
+	levels map[string]Level
+}
+
+var levels = &levelRegistry{
+	levels: make(map[string]Level),
+}
+
+//SetLevel sets the loglevel for the logger with the given dotted name, and
+//for any descendant that doesn't have a more specific override of its own.
+func SetLevel(name string, level Level) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	levels.levels[name] = level
+}
+
+//SetLevel sets the loglevel of this particular logger.
+func (l *Logger) SetLevel(level Level) {
+	SetLevel(l.name, level)
+}
+
+//effectiveLevel walks up the dotted name hierarchy, e.g. "app.db.pool" ->
+//"app.db" -> "app", returning the level of the closest ancestor with an
+//explicit override, or the root logger's level if none is set.
+func effectiveLevel(name string) Level {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	for n := name; n != ""; {
+		if level, ok := levels.levels[n]; ok {
+			return level
+		}
+		idx := strings.LastIndex(n, ".")
+		if idx < 0 {
+			break
+		}
+		n = n[:idx]
+	}
+
+	return root.level
+}
+
+//IsEnabled reports whether a message at the given level would actually be
+//emitted by this logger, so callers can skip building expensive arguments
+//when it wouldn't be.
+func (l *Logger) IsEnabled(level Level) bool {
+	return level >= effectiveLevel(l.name)
+}
+
+//SetLevelsFromEnv reads envVar and applies a comma-separated list of
+//"name:level" overrides from it, e.g. with MYAPP_LOG set to
+//"app.db:debug,app.http:warn", SetLevelsFromEnv("MYAPP_LOG") sets "app.db"
+//to DEBUG and "app.http" to WARN. Missing or empty env vars are a no-op.
+func SetLevelsFromEnv(envVar string) error {
+	spec, ok := os.LookupEnv(envVar)
+	if !ok || spec == "" {
+		return nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("micrologging: malformed level entry %q in %s", entry, envVar)
+		}
+
+		level, err := LevelFromString(parts[1])
+		if err != nil {
+			return err
+		}
+
+		SetLevel(strings.TrimSpace(parts[0]), level)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package micrologging
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEffectiveLevelInheritsFromAncestor(t *testing.T) {
+	SetLevel("app.db", WARN)
+	defer delete(levels.levels, "app.db")
+
+	if got := effectiveLevel("app.db.pool"); got != WARN {
+		t.Fatalf("expected app.db.pool to inherit WARN from app.db, got %v", got)
+	}
+	if got := effectiveLevel("app.http"); got != root.level {
+		t.Fatalf("expected app.http with no override to fall back to the root level, got %v", got)
+	}
+}
+
+func TestLoggerIsEnabledRespectsOwnOverride(t *testing.T) {
+	l := GetLogger("test.isenabled")
+	l.SetLevel(ERROR)
+	defer delete(levels.levels, "test.isenabled")
+
+	if l.IsEnabled(WARN) {
+		t.Fatalf("expected WARN to be disabled once the logger's level is ERROR")
+	}
+	if !l.IsEnabled(ERROR) {
+		t.Fatalf("expected ERROR to be enabled")
+	}
+}
+
+func TestSetLevelsFromEnvParsesCommaSeparatedList(t *testing.T) {
+	const envVar = "MICROLOGGING_TEST_LOG"
+	os.Setenv(envVar, "app.db:debug,app.http:warn")
+	defer os.Unsetenv(envVar)
+	defer delete(levels.levels, "app.db")
+	defer delete(levels.levels, "app.http")
+
+	if err := SetLevelsFromEnv(envVar); err != nil {
+		t.Fatalf("SetLevelsFromEnv: %v", err)
+	}
+
+	if got := effectiveLevel("app.db"); got != DEBUG {
+		t.Fatalf("expected app.db=DEBUG, got %v", got)
+	}
+	if got := effectiveLevel("app.http"); got != WARN {
+		t.Fatalf("expected app.http=WARN, got %v", got)
+	}
+}
+
+func TestSetLevelsFromEnvRejectsMalformedEntry(t *testing.T) {
+	const envVar = "MICROLOGGING_TEST_LOG_BAD"
+	os.Setenv(envVar, "app.db")
+	defer os.Unsetenv(envVar)
+
+	if err := SetLevelsFromEnv(envVar); err == nil {
+		t.Fatalf("expected an error for a malformed level entry")
+	}
+}
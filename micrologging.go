@@ -1,6 +1,7 @@
 package micrologging
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/syslog"
@@ -49,20 +50,35 @@ func (l Level) String() string {
 // construct your own Logger, that will be a child of the root logger.
 type rootLogger struct {
 	mu      sync.Mutex
-	outputs []io.Writer
+	outputs []rootOutput
 	level   Level
 }
 
+// rootOutput pairs a writer with the encoder used to render records for it,
+// so a single record can be rendered as text for one output and JSON for
+// another.
+type rootOutput struct {
+	writer  io.Writer
+	encoder Encoder
+}
+
 var root *rootLogger
 
+// fieldsContextKey is the type used to stash fields in a context.Context via
+// ContextWithFields, kept unexported so it can't collide with keys from
+// other packages.
+type fieldsContextKey struct{}
+
 type Logger struct {
-	name string
+	name   string
+	mu     sync.RWMutex
+	fields map[string]interface{}
 }
 
 func init() {
 
-	outputs := make([]io.Writer, 1)
-	outputs[0] = os.Stdout
+	outputs := make([]rootOutput, 1)
+	outputs[0] = rootOutput{writer: os.Stdout, encoder: AutoEncoderFor(os.Stdout)}
 
 	root = &rootLogger{
 		outputs: outputs,
@@ -101,9 +117,15 @@ func LevelFromString(str string) (Level, error) {
 
 //SetRootOutput assigns an io.Writer to root logger
 func AddRootOutput(output io.Writer) {
+	AddRootOutputWithEncoder(output, TextEncoder{})
+}
+
+//AddRootOutputWithEncoder assigns an io.Writer to the root logger, rendering
+//records for it with the given Encoder instead of the default text format.
+func AddRootOutputWithEncoder(output io.Writer, encoder Encoder) {
 	root.mu.Lock()
 	defer root.mu.Unlock()
-	root.outputs = append(root.outputs, output)
+	root.outputs = append(root.outputs, rootOutput{writer: output, encoder: encoder})
 }
 
 //SetRootLevel sets the loglvevel of the root logger
@@ -114,117 +136,165 @@ func SetRootLevel(level Level) {
 //GetLogger constructs the child logger of the root with specified name
 func GetLogger(name string) *Logger {
 	return &Logger{
-		name,
+		name: name,
+	}
+}
+
+//With returns a child logger carrying an immutable copy of the current
+//fields merged with the given ones. The parent logger is left untouched, so
+//it is safe to branch several children off the same Logger.
+func (l *Logger) With(fields map[string]interface{}) *Logger {
+	l.mu.RLock()
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	l.mu.RUnlock()
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &Logger{
+		name:   l.name,
+		fields: merged,
 	}
 }
 
+//WithError returns a child logger carrying the given error under the
+//"error" field.
+func (l *Logger) WithError(err error) *Logger {
+	return l.With(map[string]interface{}{"error": err.Error()})
+}
+
+//WithContext returns a child logger carrying the fields stashed in ctx via
+//ContextWithFields, if any. If ctx carries no fields, it behaves like a
+//no-op copy of l.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	fields, _ := ctx.Value(fieldsContextKey{}).(map[string]interface{})
+	return l.With(fields)
+}
+
+//ContextWithFields returns a copy of ctx carrying fields, to be picked up
+//later by Logger.WithContext.
+func ContextWithFields(ctx context.Context, fields map[string]interface{}) context.Context {
+	return context.WithValue(ctx, fieldsContextKey{}, fields)
+}
+
 //Printf logs the line with given loglevel, formatted according to format, using
 //the root logger
 func (l *Logger) Printf(level Level, format string, messages ...interface{}) {
-	root.printf(level, format, l.name, messages...)
+	l.printfDepth(level, 1, format, messages...)
 }
 
-func (l *rootLogger) printf(level Level, format, name string, messages ...interface{}) {
-
-	if level < l.level {
-		return
-	}
-
-	b := &strings.Builder{}
+//PrintfDepth is like Printf, but skip lets code that wraps Logger in its
+//own helper adjust the captured caller past its own stack frame - a
+//wrapper one frame deep should pass skip=1, two frames deep skip=2, and
+//so on.
+func (l *Logger) PrintfDepth(level Level, skip int, format string, messages ...interface{}) {
+	l.printfDepth(level, skip+1, format, messages...)
+}
 
-	b.WriteString("(" + time.Now().Format(timeFormat) + ") ")
+func (l *Logger) printfDepth(level Level, skip int, format string, messages ...interface{}) {
+	l.mu.RLock()
+	fields := l.fields
+	l.mu.RUnlock()
+	root.printf(level, format, l.name, fields, callerSkipBase+skip, messages...)
+}
 
-	b.WriteString("[")
-	b.WriteString(level.String())
-	b.WriteString("] ")
+func (l *rootLogger) printf(level Level, format, name string, fields map[string]interface{}, skip int, messages ...interface{}) {
 
-	if name != "" {
-		b.WriteString("(")
-		b.WriteString(name)
-		b.WriteString(") ")
+	if level < effectiveLevel(name) {
+		return
 	}
 
+	message := format
 	if len(messages) > 0 {
-		b.WriteString(fmt.Sprintf(format, messages...))
-	} else {
-		b.WriteString(format)
+		message = fmt.Sprintf(format, messages...)
 	}
 
-	out := strings.TrimSpace(b.String())
+	record := Record{
+		Time:    time.Now(),
+		Level:   level,
+		Name:    name,
+		Caller:  caller(skip),
+		Message: message,
+		Fields:  fields,
+	}
 
 	for _, output := range l.outputs {
-		if syslogWriter, ok := output.(*syslog.Writer); ok {
+		if syslogWriter, ok := output.writer.(*syslog.Writer); ok {
 
-			out := fmt.Sprintf(format, messages...)
+			encoded := string(output.encoder.Encode(record))
 
 			switch level {
 			case TRACE:
 				fallthrough
 			case DEBUG:
-				syslogWriter.Debug(out)
+				syslogWriter.Debug(encoded)
 			case INFO:
-				syslogWriter.Info(out)
+				syslogWriter.Info(encoded)
 			case WARN:
-				syslogWriter.Warning(out)
+				syslogWriter.Warning(encoded)
 			case ERROR:
-				syslogWriter.Err(out)
+				syslogWriter.Err(encoded)
 			case FATAL:
-				syslogWriter.Crit(out)
+				syslogWriter.Crit(encoded)
 			default:
-				syslogWriter.Info(out)
+				syslogWriter.Info(encoded)
 			}
 			continue
 		}
 
-		fmt.Fprintln(output, out)
+		fmt.Fprintln(output.writer, string(output.encoder.Encode(record)))
 	}
 
 }
 
 func (l *Logger) Trace(format string, messages ...interface{}) {
-	l.Printf(TRACE, format, messages...)
+	l.printfDepth(TRACE, 1, format, messages...)
 }
 
 func (l *Logger) Debug(format string, messages ...interface{}) {
-	l.Printf(DEBUG, format, messages...)
+	l.printfDepth(DEBUG, 1, format, messages...)
 }
 
 func (l *Logger) Info(format string, messages ...interface{}) {
-	l.Printf(INFO, format, messages...)
+	l.printfDepth(INFO, 1, format, messages...)
 }
 
 func (l *Logger) Warn(format string, messages ...interface{}) {
-	l.Printf(WARN, format, messages...)
+	l.printfDepth(WARN, 1, format, messages...)
 }
 
 func (l *Logger) Error(format string, messages ...interface{}) {
-	l.Printf(ERROR, format, messages...)
+	l.printfDepth(ERROR, 1, format, messages...)
 }
 
 func (l *Logger) Fatal(format string, messages ...interface{}) {
-	l.Printf(FATAL, format, messages...)
+	l.printfDepth(FATAL, 1, format, messages...)
 }
 
 func Trace(format string, messages ...interface{}) {
-	root.printf(TRACE, format, "", messages...)
+	root.printf(TRACE, format, "", nil, callerSkipBase, messages...)
 }
 
 func Debug(format string, messages ...interface{}) {
-	root.printf(DEBUG, format, "", messages...)
+	root.printf(DEBUG, format, "", nil, callerSkipBase, messages...)
 }
 
 func Info(format string, messages ...interface{}) {
-	root.printf(INFO, format, "", messages...)
+	root.printf(INFO, format, "", nil, callerSkipBase, messages...)
 }
 
 func Warn(format string, messages ...interface{}) {
-	root.printf(WARN, format, "", messages...)
+	root.printf(WARN, format, "", nil, callerSkipBase, messages...)
 }
 
 func Error(format string, messages ...interface{}) {
-	root.printf(ERROR, format, "", messages...)
+	root.printf(ERROR, format, "", nil, callerSkipBase, messages...)
 }
 
 func Fatal(format string, messages ...interface{}) {
-	root.printf(FATAL, format, "", messages...)
+	root.printf(FATAL, format, "", nil, callerSkipBase, messages...)
 }
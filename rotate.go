@@ -0,0 +1,266 @@
+package micrologging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RotateOptions configures the rotation policy of a RotatingFileWriter.
+type RotateOptions struct {
+	// MaxSizeBytes is the size a file is allowed to reach before it is
+	// rotated out. Zero disables size-based rotation.
+	MaxSizeBytes int64
+	// MaxAgeDuration is how long a backup is kept before being pruned.
+	// Zero disables age-based pruning.
+	MaxAgeDuration time.Duration
+	// MaxBackups is the most backups kept; the oldest beyond it are
+	// pruned, after any age-based pruning. Zero disables count-based
+	// pruning.
+	MaxBackups int
+	// Compress gzips backups in a background goroutine after rotation.
+	Compress bool
+	// LocalTime uses local time instead of UTC for backup timestamps.
+	LocalTime bool
+}
+
+// RotatingFileWriter is an io.Writer backed by a file on disk. Once the file
+// would exceed RotateOptions.MaxSizeBytes, it is closed, renamed to a
+// timestamped backup and reopened, and backups exceeding MaxBackups or
+// MaxAgeDuration are pruned.
+type RotatingFileWriter struct {
+	mu   sync.Mutex
+	path string
+	opts RotateOptions
+	file *os.File
+	size int64
+
+	background chan func()
+}
+
+//NewRotatingFileWriter opens (or creates) the file at path for append,
+//ready to be handed to AddRootOutput.
+func NewRotatingFileWriter(path string, opts RotateOptions) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{
+		path:       path,
+		opts:       opts,
+		background: make(chan func(), 4),
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	go w.runBackground()
+
+	return w, nil
+}
+
+//runBackground drains queued compress/prune work one task at a time, so a
+//rotation's compress always finishes before its own prune - and before the
+//next rotation's compress/prune - runs, instead of racing over the same
+//backup files.
+func (w *RotatingFileWriter) runBackground() {
+	for task := range w.background {
+		task()
+	}
+}
+
+func (w *RotatingFileWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+//Write implements io.Writer, rotating the underlying file first if p would
+//push it past RotateOptions.MaxSizeBytes.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.opts.MaxSizeBytes > 0 && w.size+int64(len(p)) > w.opts.MaxSizeBytes {
+		if err := w.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+//Reopen closes and reopens the underlying file without renaming it, for
+//external tools (e.g. logrotate) that have already moved it aside. See
+//InstallSignalReopen to wire this up to SIGHUP.
+func (w *RotatingFileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != nil {
+		w.file.Close()
+	}
+
+	return w.open()
+}
+
+//uniqueBackupPath returns path.ts with a ".N" suffix appended if needed, so
+//two rotations landing in the same wall-clock second (the format below
+//only has 1-second resolution) don't collide and silently clobber one
+//another via os.Rename.
+func (w *RotatingFileWriter) uniqueBackupPath(ts time.Time) string {
+	base := w.path + "." + ts.Format("2006-01-02T15-04-05")
+
+	candidate := base
+	for i := 1; ; i++ {
+		// A backup may already have been compressed to candidate+".gz",
+		// which compressBackup would otherwise overwrite.
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			if _, err := os.Stat(candidate + ".gz"); os.IsNotExist(err) {
+				return candidate
+			}
+		}
+		candidate = fmt.Sprintf("%s.%d", base, i)
+	}
+}
+
+func (w *RotatingFileWriter) rotateLocked() error {
+	if w.file != nil {
+		w.file.Close()
+	}
+
+	ts := time.Now()
+	if !w.opts.LocalTime {
+		ts = ts.UTC()
+	}
+
+	backupPath := w.uniqueBackupPath(ts)
+	if err := os.Rename(w.path, backupPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	opts := w.opts
+	path := w.path
+	w.background <- func() {
+		if opts.Compress {
+			compressBackup(backupPath)
+		}
+		pruneBackups(path, opts)
+	}
+
+	return w.open()
+}
+
+func compressBackup(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		return
+	}
+	if err := gz.Close(); err != nil {
+		return
+	}
+
+	os.Remove(path)
+}
+
+//pruneBackups removes backups of path exceeding opts.MaxBackups or older
+//than opts.MaxAgeDuration. Backups are ordered oldest first by their
+//fixed-width timestamp suffix, then by the ".N" suffix uniqueBackupPath adds
+//to same-second backups, ignoring any ".gz".
+func pruneBackups(path string, opts RotateOptions) {
+	if opts.MaxBackups <= 0 && opts.MaxAgeDuration <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+
+	stampEnd := len(filepath.Join(dir, base)) + len(".2006-01-02T15-04-05")
+	sort.Slice(backups, func(i, j int) bool {
+		a := strings.TrimSuffix(backups[i], ".gz")
+		b := strings.TrimSuffix(backups[j], ".gz")
+		if len(a) >= stampEnd && len(b) >= stampEnd && len(a) != len(b) && a[:stampEnd] == b[:stampEnd] {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+
+	if opts.MaxAgeDuration > 0 {
+		cutoff := time.Now().Add(-opts.MaxAgeDuration)
+		kept := backups[:0]
+		for _, backup := range backups {
+			info, err := os.Stat(backup)
+			if err == nil && info.ModTime().Before(cutoff) {
+				os.Remove(backup)
+				continue
+			}
+			kept = append(kept, backup)
+		}
+		backups = kept
+	}
+
+	if opts.MaxBackups > 0 && len(backups) > opts.MaxBackups {
+		for _, backup := range backups[:len(backups)-opts.MaxBackups] {
+			os.Remove(backup)
+		}
+	}
+}
+
+//InstallSignalReopen spawns a goroutine that calls Reopen whenever the
+//process receives SIGHUP, for use alongside external logrotate tools that
+//rename the file out from under us.
+func (w *RotatingFileWriter) InstallSignalReopen() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		for range sighup {
+			w.Reopen()
+		}
+	}()
+}